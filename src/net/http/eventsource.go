@@ -0,0 +1,163 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sseDefaultRetry is the reconnection time, which caps reconnection backoff,
+// in effect until the server sends a retry: field, per the HTML Living
+// Standard's "reconnection time" concept.
+const sseDefaultRetry = 3 * time.Second
+
+// sseInitialBackoff is the delay before the first reconnection attempt
+// following a failed one, before exponential growth kicks in. It is
+// intentionally smaller than sseDefaultRetry so that a connection that
+// merely blips reconnects quickly, rather than always waiting the full
+// retry: value.
+const sseInitialBackoff = 1 * time.Second
+
+// sseMaxBackoff is a last-resort safety net on top of the server's retry:
+// value, which is otherwise the actual ceiling on reconnection backoff; it
+// only matters if a server sends an unreasonably large retry:.
+const sseMaxBackoff = 5 * time.Minute
+
+// SSEEvent is a single event parsed from a text/event-stream response, as
+// produced by an EventSource.
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+// initialReconnectBackoff returns the delay before the first reconnection
+// attempt after a disconnect, given the server's most recently declared
+// retry: value (or sseDefaultRetry if none has been sent yet).
+func initialReconnectBackoff(retry time.Duration) time.Duration {
+	if sseInitialBackoff < retry {
+		return sseInitialBackoff
+	}
+	return retry
+}
+
+// nextReconnectBackoff returns the delay before the next reconnection
+// attempt after prev's attempt failed to connect. Backoff doubles each time,
+// capped at retry (the server's declared reconnection time, which callers
+// should otherwise treat as the target cadence) and, as a last-resort
+// safety net, at sseMaxBackoff.
+func nextReconnectBackoff(prev, retry time.Duration) time.Duration {
+	next := prev * 2
+	if next > retry {
+		next = retry
+	}
+	if next > sseMaxBackoff {
+		next = sseMaxBackoff
+	}
+	return next
+}
+
+// readEventStream parses body as a text/event-stream, per
+// https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation,
+// delivering each dispatched event on events. It returns the last-event-ID
+// and reconnection time in effect when the stream ended, along with any
+// error other than io.EOF. It stops promptly once ctx is done, even if
+// events isn't being drained, so a canceled EventSource can't wedge its
+// caller on a blocked channel send.
+func readEventStream(ctx context.Context, body io.Reader, events chan<- SSEEvent, lastEventID string, retry time.Duration) (string, time.Duration, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Split(scanSSELine)
+
+	var ev SSEEvent
+	var data strings.Builder
+	hasData := false
+
+	dispatch := func() error {
+		if hasData {
+			s := data.String()
+			ev.Data = strings.TrimSuffix(s, "\n")
+			ev.ID = lastEventID
+			ev.Retry = retry
+			if ev.Event == "" {
+				ev.Event = "message"
+			}
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		ev = SSEEvent{}
+		data.Reset()
+		hasData = false
+		return nil
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if err := dispatch(); err != nil {
+				return lastEventID, retry, err
+			}
+			continue
+		}
+		if line[0] == ':' {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			ev.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			hasData = true
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseInt(value, 10, 64); err == nil && ms >= 0 {
+				retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return lastEventID, retry, scanner.Err()
+}
+
+// scanSSELine is a bufio.SplitFunc that splits on "\n", "\r\n", or a bare
+// "\r", as required by the event stream line-parsing algorithm.
+func scanSSELine(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	for i, b := range data {
+		switch b {
+		case '\n':
+			return i + 1, data[:i], nil
+		case '\r':
+			if i+1 == len(data) && !atEOF {
+				// Might be the start of "\r\n"; wait for more data.
+				return 0, nil, nil
+			}
+			if i+1 < len(data) && data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
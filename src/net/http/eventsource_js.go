@@ -0,0 +1,160 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build js && wasm
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventSource is a client for the Server-Sent Events protocol. It consumes a
+// text/event-stream response body obtained through the Fetch-based Transport
+// and reconnects automatically, following the HTML Living Standard's event
+// stream processing model.
+//
+// EventSource is only available on js/wasm, where it is built on top of the
+// streaming Response.Body (streamReader) that Transport.RoundTrip already
+// produces for Fetch responses.
+type EventSource struct {
+	// Events delivers parsed events in arrival order. It is closed once the
+	// EventSource's context is done.
+	Events <-chan SSEEvent
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewEventSource connects to url and returns an EventSource that delivers
+// its events on the returned value's Events channel. The initial connection
+// is made synchronously: a non-2xx status or a Content-Type other than
+// text/event-stream is returned as an error before any reconnection logic
+// runs. After that, NewEventSource reconnects automatically on stream errors
+// or server-initiated closes, using an exponential backoff capped by the
+// most recently received retry: value (or sseDefaultRetry if none has been
+// sent yet).
+//
+// The EventSource stops and its Events channel is closed when ctx is done.
+func NewEventSource(ctx context.Context, url string) (*EventSource, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	es := &EventSource{cancel: cancel, done: make(chan struct{})}
+
+	resp, err := es.connect(ctx, url, "")
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	events := make(chan SSEEvent)
+	es.Events = events
+	go es.run(ctx, url, resp, events)
+	return es, nil
+}
+
+// Close stops the EventSource and releases the underlying connection. It is
+// safe to call Close more than once.
+func (es *EventSource) Close() error {
+	es.cancel()
+	<-es.done
+	return nil
+}
+
+// connect performs a single text/event-stream request, validating the
+// response before any bytes are parsed as events.
+func (es *EventSource) connect(ctx context.Context, url, lastEventID string) (*Response, error) {
+	req, err := NewRequestWithContext(ctx, MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	// Use the Transport's own cache control, not just the Cache-Control
+	// request header: js.fetch:cache drives the Fetch API's cache init
+	// option directly, which is what actually keeps the browser's HTTP
+	// cache from serving a stale event stream.
+	req.Header.Set(jsFetchCache, "no-store")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("net/http: EventSource: unexpected status %s", resp.Status)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		resp.Body.Close()
+		return nil, fmt.Errorf("net/http: EventSource: unexpected Content-Type %q", ct)
+	}
+	return resp, nil
+}
+
+// run owns resp and all subsequent reconnections, delivering parsed events
+// on events until ctx is done. It closes the active response body as soon as
+// ctx is done, which is what lets Close interrupt a blocked stream read: the
+// underlying streamReader.Close cancels the Fetch ReadableStream reader,
+// unblocking the pending JS promise the same way an aborted request would.
+func (es *EventSource) run(ctx context.Context, url string, resp *Response, events chan<- SSEEvent) {
+	defer close(es.done)
+	defer close(events)
+
+	var mu sync.Mutex
+	current := resp
+	go func() {
+		<-ctx.Done()
+		mu.Lock()
+		defer mu.Unlock()
+		current.Body.Close()
+	}()
+	setCurrent := func(r *Response) {
+		mu.Lock()
+		current = r
+		if ctx.Err() != nil {
+			// The closer above may already have run; close r here instead.
+			r.Body.Close()
+		}
+		mu.Unlock()
+	}
+
+	var lastEventID string
+	retry := sseDefaultRetry
+	for {
+		lastEventID, retry, _ = readEventStream(ctx, resp.Body, events, lastEventID, retry)
+		resp.Body.Close()
+		if ctx.Err() != nil {
+			return
+		}
+		// Every disconnect, whether a network error or a clean
+		// server-initiated close, starts reconnection attempts at
+		// initialReconnectBackoff, growing toward the server's most
+		// recently declared retry: value as attempts keep failing.
+		backoff := initialReconnectBackoff(retry)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			var err error
+			resp, err = es.connect(ctx, url, lastEventID)
+			if err == nil {
+				setCurrent(resp)
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			backoff = nextReconnectBackoff(backoff, retry)
+		}
+	}
+}
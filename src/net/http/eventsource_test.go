@@ -0,0 +1,189 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"bufio"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadEventStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   []SSEEvent
+	}{
+		{
+			name:   "basic message",
+			stream: "data: hello\n\n",
+			want:   []SSEEvent{{Event: "message", Data: "hello"}},
+		},
+		{
+			name:   "named event",
+			stream: "event: add\ndata: hello\nid: 1\n\n",
+			want:   []SSEEvent{{Event: "add", Data: "hello", ID: "1"}},
+		},
+		{
+			name:   "multiline data is joined with newlines",
+			stream: "data: line one\ndata: line two\n\n",
+			want:   []SSEEvent{{Event: "message", Data: "line one\nline two"}},
+		},
+		{
+			name:   "comments are ignored",
+			stream: ": this is a comment\ndata: hello\n\n",
+			want:   []SSEEvent{{Event: "message", Data: "hello"}},
+		},
+		{
+			name:   "event with no data is not dispatched",
+			stream: "event: add\nid: 1\n\n",
+			want:   nil,
+		},
+		{
+			name:   "CRLF line endings",
+			stream: "data: hello\r\n\r\n",
+			want:   []SSEEvent{{Event: "message", Data: "hello"}},
+		},
+		{
+			name:   "bare CR line endings",
+			stream: "data: hello\r\r",
+			want:   []SSEEvent{{Event: "message", Data: "hello"}},
+		},
+		{
+			name:   "id persists across events until reset",
+			stream: "id: 1\ndata: a\n\ndata: b\n\nid: \ndata: c\n\n",
+			want: []SSEEvent{
+				{Event: "message", Data: "a", ID: "1"},
+				{Event: "message", Data: "b", ID: "1"},
+				{Event: "message", Data: "c", ID: ""},
+			},
+		},
+		{
+			name:   "field with no colon is treated as a field name with an empty value",
+			stream: "data\n\n",
+			want:   []SSEEvent{{Event: "message", Data: ""}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := make(chan SSEEvent, len(tt.want)+1)
+			_, _, err := readEventStream(context.Background(), strings.NewReader(tt.stream), events, "", sseDefaultRetry)
+			if err != nil {
+				t.Fatalf("readEventStream: %v", err)
+			}
+			close(events)
+			var got []SSEEvent
+			for ev := range events {
+				ev.Retry = 0 // not under test here
+				got = append(got, ev)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadEventStreamRetryField(t *testing.T) {
+	events := make(chan SSEEvent, 1)
+	_, retry, err := readEventStream(context.Background(), strings.NewReader("retry: 5000\ndata: hi\n\n"), events, "", sseDefaultRetry)
+	if err != nil {
+		t.Fatalf("readEventStream: %v", err)
+	}
+	if want := 5 * time.Second; retry != want {
+		t.Errorf("retry = %v, want %v", retry, want)
+	}
+	ev := <-events
+	if ev.Retry != 5*time.Second {
+		t.Errorf("ev.Retry = %v, want %v", ev.Retry, 5*time.Second)
+	}
+}
+
+func TestReadEventStreamCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	events := make(chan SSEEvent) // unbuffered and never drained
+	_, _, err := readEventStream(ctx, strings.NewReader("data: hello\n\n"), events, "", sseDefaultRetry)
+	if err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestReconnectBackoffGrows(t *testing.T) {
+	const retry = 30 * time.Second
+
+	backoff := initialReconnectBackoff(retry)
+	if backoff <= 0 || backoff >= retry {
+		t.Fatalf("initialReconnectBackoff(%v) = %v, want a positive delay smaller than retry", retry, backoff)
+	}
+
+	seen := map[time.Duration]bool{backoff: true}
+	for i := 0; i < 10 && backoff < retry; i++ {
+		next := nextReconnectBackoff(backoff, retry)
+		if next <= backoff {
+			t.Fatalf("nextReconnectBackoff(%v, %v) = %v, want strictly greater than prev (until capped at retry)", backoff, retry, next)
+		}
+		if next > retry {
+			t.Fatalf("nextReconnectBackoff(%v, %v) = %v, want capped at retry = %v", backoff, retry, next, retry)
+		}
+		seen[next] = true
+		backoff = next
+	}
+	if len(seen) < 3 {
+		t.Fatalf("backoff only took on %d distinct values (%v), want it to actually grow across several failures", len(seen), seen)
+	}
+	if backoff != retry {
+		t.Fatalf("backoff settled at %v without ever reaching the retry cap %v", backoff, retry)
+	}
+}
+
+func TestReconnectBackoffCappedBySseMaxBackoff(t *testing.T) {
+	const retry = 24 * time.Hour // far larger than sseMaxBackoff
+
+	backoff := initialReconnectBackoff(retry)
+	for i := 0; i < 64; i++ {
+		backoff = nextReconnectBackoff(backoff, retry)
+		if backoff > sseMaxBackoff {
+			t.Fatalf("nextReconnectBackoff exceeded sseMaxBackoff (%v): got %v", sseMaxBackoff, backoff)
+		}
+	}
+	if backoff != sseMaxBackoff {
+		t.Errorf("backoff = %v after repeated failures with a huge retry, want it to settle at sseMaxBackoff = %v", backoff, sseMaxBackoff)
+	}
+}
+
+func TestScanSSELine(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"lf", "a\nb\nc", []string{"a", "b", "c"}},
+		{"crlf", "a\r\nb\r\nc", []string{"a", "b", "c"}},
+		{"bare cr", "a\rb\rc", []string{"a", "b", "c"}},
+		{"mixed", "a\nb\r\nc\rd", []string{"a", "b", "c", "d"}},
+		{"trailing newline", "a\nb\n", []string{"a", "b"}},
+		{"no trailing newline", "a\nb", []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := bufio.NewScanner(strings.NewReader(tt.in))
+			scanner.Split(scanSSELine)
+			var got []string
+			for scanner.Scan() {
+				got = append(got, scanner.Text())
+			}
+			if err := scanner.Err(); err != nil {
+				t.Fatalf("scan error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
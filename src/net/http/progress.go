@@ -0,0 +1,192 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ProgressPhase identifies the stage of a js/wasm Fetch exchange that a
+// ProgressEvent reports on.
+type ProgressPhase string
+
+const (
+	ProgressStarted   ProgressPhase = "started"    // the fetch call is about to be made
+	ProgressHeaders   ProgressPhase = "headers"    // response headers have arrived
+	ProgressFirstByte ProgressPhase = "first-byte" // the first response body byte has arrived
+	ProgressUpload    ProgressPhase = "upload"     // BytesSent has advanced
+	ProgressDownload  ProgressPhase = "download"   // BytesReceived has advanced
+	ProgressDone      ProgressPhase = "done"       // the response body has been fully read
+	ProgressAborted   ProgressPhase = "aborted"    // the request's context was canceled
+)
+
+// ProgressEvent reports upload/download progress for a single js/wasm Fetch
+// exchange. BytesSent and BytesReceived are cumulative; TotalSent and
+// TotalReceived are the Content-Length of the request and response bodies
+// respectively, or -1 if unknown.
+type ProgressEvent struct {
+	Phase         ProgressPhase
+	BytesSent     int64
+	TotalSent     int64
+	BytesReceived int64
+	TotalReceived int64
+}
+
+// ProgressFunc is called as a js/wasm Transport exchange progresses. See
+// WithProgress.
+type ProgressFunc func(ProgressEvent)
+
+// ProgressOptions configures progress reporting for a single request made
+// through the js/wasm Transport.
+type ProgressOptions struct {
+	// Func is called for every lifecycle event (started, headers,
+	// first-byte, done, aborted) and, subject to MinInterval and MinDelta,
+	// for incremental upload/download byte counts.
+	Func ProgressFunc
+
+	// ChunkSize, if positive, overrides the Transport's writeBufferSize as
+	// the autoAllocateChunkSize of the ReadableStream used to stream the
+	// request body, i.e. how many bytes are read from the body per pull
+	// callback.
+	ChunkSize int
+
+	// MinInterval and MinDelta debounce ProgressUpload and ProgressDownload
+	// events: once one has been sent, the next is sent only after
+	// MinInterval has elapsed or the byte count has advanced by at least
+	// MinDelta, whichever comes first. A zero MinInterval or MinDelta
+	// disables that dimension of debouncing. Lifecycle events are never
+	// debounced.
+	MinInterval time.Duration
+	MinDelta    int64
+}
+
+type progressContextKey struct{}
+
+// WithProgress returns a copy of ctx that, when used with a request made
+// through a js/wasm Transport, reports that request's upload/download
+// progress through opts.
+func WithProgress(ctx context.Context, opts ProgressOptions) context.Context {
+	return context.WithValue(ctx, progressContextKey{}, &opts)
+}
+
+func progressOptionsFromContext(ctx context.Context) *ProgressOptions {
+	opts, _ := ctx.Value(progressContextKey{}).(*ProgressOptions)
+	return opts
+}
+
+// progressReporter tracks cumulative upload/download byte counts for one
+// Fetch exchange and applies ProgressOptions' debouncing before calling
+// Func. A nil *progressReporter is valid and every method on it is a no-op,
+// so call sites don't need to special-case the no-progress-configured case.
+type progressReporter struct {
+	opts *ProgressOptions
+
+	mu                           sync.Mutex
+	bytesSent, totalSent         int64
+	bytesReceived, totalReceived int64
+	lastSent, lastReceived       int64
+	lastSentAt, lastReceivedAt   time.Time
+	sentOnce, receivedOnce       bool
+}
+
+// newProgressReporter returns a reporter for opts, or nil if opts is nil or
+// has no Func, in which case all reporting is skipped.
+func newProgressReporter(opts *ProgressOptions, totalSent, totalReceived int64) *progressReporter {
+	if opts == nil || opts.Func == nil {
+		return nil
+	}
+	return &progressReporter{opts: opts, totalSent: totalSent, totalReceived: totalReceived}
+}
+
+// setTotalReceived records the response Content-Length once it is known.
+func (r *progressReporter) setTotalReceived(total int64) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.totalReceived = total
+	r.mu.Unlock()
+}
+
+// lifecycle reports a non-debounced lifecycle event.
+func (r *progressReporter) lifecycle(phase ProgressPhase) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	ev := r.eventLocked(phase)
+	r.mu.Unlock()
+	r.opts.Func(ev)
+}
+
+// sent records n additional uploaded bytes and, unless debounced, reports a
+// ProgressUpload event.
+func (r *progressReporter) sent(n int) {
+	if r == nil || n <= 0 {
+		return
+	}
+	r.mu.Lock()
+	r.bytesSent += int64(n)
+	due := r.dueLocked(r.sentOnce, r.bytesSent, r.lastSent, r.lastSentAt)
+	var ev ProgressEvent
+	if due {
+		r.lastSent, r.lastSentAt, r.sentOnce = r.bytesSent, time.Now(), true
+		ev = r.eventLocked(ProgressUpload)
+	}
+	r.mu.Unlock()
+	if due {
+		r.opts.Func(ev)
+	}
+}
+
+// received records n additional downloaded bytes and, unless debounced,
+// reports a ProgressDownload event.
+func (r *progressReporter) received(n int) {
+	if r == nil || n <= 0 {
+		return
+	}
+	r.mu.Lock()
+	r.bytesReceived += int64(n)
+	due := r.dueLocked(r.receivedOnce, r.bytesReceived, r.lastReceived, r.lastReceivedAt)
+	var ev ProgressEvent
+	if due {
+		r.lastReceived, r.lastReceivedAt, r.receivedOnce = r.bytesReceived, time.Now(), true
+		ev = r.eventLocked(ProgressDownload)
+	}
+	r.mu.Unlock()
+	if due {
+		r.opts.Func(ev)
+	}
+}
+
+// dueLocked reports whether a debounced event should fire, given whether one
+// has ever fired before, the new and previously-reported byte counts, and
+// the time of the previous report. r.mu must be held.
+func (r *progressReporter) dueLocked(reportedBefore bool, bytes, lastBytes int64, lastAt time.Time) bool {
+	if !reportedBefore || (r.opts.MinInterval == 0 && r.opts.MinDelta == 0) {
+		return true
+	}
+	if r.opts.MinInterval > 0 && time.Since(lastAt) >= r.opts.MinInterval {
+		return true
+	}
+	if r.opts.MinDelta > 0 && bytes-lastBytes >= r.opts.MinDelta {
+		return true
+	}
+	return false
+}
+
+// eventLocked builds the ProgressEvent for phase from the current counters.
+// r.mu must be held.
+func (r *progressReporter) eventLocked(phase ProgressPhase) ProgressEvent {
+	return ProgressEvent{
+		Phase:         phase,
+		BytesSent:     r.bytesSent,
+		TotalSent:     r.totalSent,
+		BytesReceived: r.bytesReceived,
+		TotalReceived: r.totalReceived,
+	}
+}
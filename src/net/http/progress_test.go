@@ -0,0 +1,119 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProgressReporterNil(t *testing.T) {
+	var r *progressReporter
+	// None of these must panic or call through a nil Func.
+	r.lifecycle(ProgressStarted)
+	r.sent(10)
+	r.received(10)
+	r.setTotalReceived(100)
+}
+
+func TestProgressReporterLifecycleNeverDebounced(t *testing.T) {
+	var got []ProgressPhase
+	r := newProgressReporter(&ProgressOptions{
+		Func:        func(ev ProgressEvent) { got = append(got, ev.Phase) },
+		MinInterval: time.Hour, // would block any byte-count update
+		MinDelta:    1 << 30,
+	}, -1, -1)
+	r.lifecycle(ProgressStarted)
+	r.lifecycle(ProgressHeaders)
+	r.lifecycle(ProgressDone)
+	want := []ProgressPhase{ProgressStarted, ProgressHeaders, ProgressDone}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i, phase := range want {
+		if got[i] != phase {
+			t.Errorf("event %d: got %v, want %v", i, got[i], phase)
+		}
+	}
+}
+
+func TestProgressReporterNoThrottleConfigured(t *testing.T) {
+	var n int
+	r := newProgressReporter(&ProgressOptions{
+		Func: func(ProgressEvent) { n++ },
+	}, -1, -1)
+	r.received(1)
+	r.received(1)
+	r.received(1)
+	if n != 3 {
+		t.Errorf("n = %d, want 3 (no MinInterval/MinDelta means every update reports)", n)
+	}
+}
+
+func TestProgressReporterMinDelta(t *testing.T) {
+	var events []ProgressEvent
+	r := newProgressReporter(&ProgressOptions{
+		Func:     func(ev ProgressEvent) { events = append(events, ev) },
+		MinDelta: 10,
+	}, -1, -1)
+	r.received(4) // first update always reports: cumulative 4
+	r.received(4) // cumulative 8, delta 4 < 10: debounced
+	r.received(3) // cumulative 11, delta 7 < 10: debounced
+	r.received(5) // cumulative 16, delta 12 >= 10: reports
+	r.received(2) // cumulative 18, delta 2 < 10: debounced
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(events), events)
+	}
+	if events[0].BytesReceived != 4 {
+		t.Errorf("events[0].BytesReceived = %d, want 4", events[0].BytesReceived)
+	}
+	if events[1].BytesReceived != 16 {
+		t.Errorf("events[1].BytesReceived = %d, want 16", events[1].BytesReceived)
+	}
+}
+
+func TestProgressReporterMinInterval(t *testing.T) {
+	var n int
+	r := newProgressReporter(&ProgressOptions{
+		Func:        func(ProgressEvent) { n++ },
+		MinInterval: time.Hour,
+	}, -1, -1)
+	r.received(1)
+	r.received(1)
+	if n != 1 {
+		t.Errorf("n = %d, want 1: second update should be debounced by MinInterval", n)
+	}
+	// Simulate enough elapsed time by reaching in and rewinding lastReceivedAt,
+	// rather than sleeping an hour in a test.
+	r.mu.Lock()
+	r.lastReceivedAt = time.Now().Add(-2 * time.Hour)
+	r.mu.Unlock()
+	r.received(1)
+	if n != 2 {
+		t.Errorf("n = %d, want 2: update past MinInterval should report", n)
+	}
+}
+
+func TestProgressReporterSentAndReceivedIndependent(t *testing.T) {
+	var events []ProgressEvent
+	r := newProgressReporter(&ProgressOptions{
+		Func: func(ev ProgressEvent) { events = append(events, ev) },
+	}, 100, 200)
+	r.sent(10)
+	r.received(20)
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(events), events)
+	}
+	if events[0].Phase != ProgressUpload || events[0].BytesSent != 10 || events[0].TotalSent != 100 {
+		t.Errorf("upload event = %+v", events[0])
+	}
+	if events[1].Phase != ProgressDownload || events[1].BytesReceived != 20 || events[1].TotalReceived != 200 {
+		t.Errorf("download event = %+v", events[1])
+	}
+	// Each event carries both counters, not just the one that changed.
+	if events[1].BytesSent != 10 {
+		t.Errorf("download event BytesSent = %d, want 10 (carried over from the upload)", events[1].BytesSent)
+	}
+}
@@ -41,6 +41,56 @@ const jsFetchCreds = "js.fetch:credentials"
 // Reference: https://developer.mozilla.org/en-US/docs/Web/API/WindowOrWorkerGlobalScope/fetch#Parameters
 const jsFetchRedirect = "js.fetch:redirect"
 
+// jsFetchCache is a Request.Header map key that, if present,
+// signals that the map entry is actually an option to the Fetch API cache setting.
+// Valid values are: "default", "no-store", "reload", "no-cache", "force-cache", "only-if-cached"
+// The default is "default".
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/WindowOrWorkerGlobalScope/fetch#Parameters
+const jsFetchCache = "js.fetch:cache"
+
+// jsFetchIntegrity is a Request.Header map key that, if present,
+// signals that the map entry is actually an option to the Fetch API integrity setting.
+// The value is a string containing a subresource integrity hash, e.g.
+// "sha256-BpfBw7ivV8q2jLiT13fxDYAe2tJllusRSZ273h2nFSE=".
+// The default is "".
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/WindowOrWorkerGlobalScope/fetch#Parameters
+const jsFetchIntegrity = "js.fetch:integrity"
+
+// jsFetchReferrer is a Request.Header map key that, if present,
+// signals that the map entry is actually an option to the Fetch API referrer setting.
+// The value is either "" (no referrer), "about:client" (the default), or a URL.
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/WindowOrWorkerGlobalScope/fetch#Parameters
+const jsFetchReferrer = "js.fetch:referrer"
+
+// jsFetchReferrerPolicy is a Request.Header map key that, if present,
+// signals that the map entry is actually an option to the Fetch API referrerPolicy setting.
+// Valid values are: "", "no-referrer", "no-referrer-when-downgrade", "same-origin",
+// "origin", "strict-origin", "origin-when-cross-origin", "strict-origin-when-cross-origin",
+// "unsafe-url"
+// The default is "".
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/WindowOrWorkerGlobalScope/fetch#Parameters
+const jsFetchReferrerPolicy = "js.fetch:referrerPolicy"
+
+// jsFetchKeepalive is a Request.Header map key that, if present,
+// signals that the map entry is actually an option to the Fetch API keepalive setting.
+// Valid values are: "0", "1"
+// The default is "0".
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/WindowOrWorkerGlobalScope/fetch#Parameters
+const jsFetchKeepalive = "js.fetch:keepalive"
+
+// jsFetchPriority is a Request.Header map key that, if present,
+// signals that the map entry is actually an option to the Fetch API priority setting.
+// Valid values are: "high", "low", "auto"
+// The default is "auto".
+//
+// Reference: https://developer.mozilla.org/en-US/docs/Web/API/WindowOrWorkerGlobalScope/fetch#Parameters
+const jsFetchPriority = "js.fetch:priority"
+
 // jsFetchMissing will be true if the Fetch API is not present in
 // the browser globals.
 var jsFetchMissing = js.Global().Get("fetch").IsUndefined()
@@ -87,6 +137,27 @@ func supportsPostRequestStreams() bool {
 	return duplexCalled && !hasContentTypeHeader
 }
 
+// validFetchEnumValues maps the jsFetch* header keys whose values the Fetch
+// spec constrains to an enumeration to the set of values it permits.
+var validFetchEnumValues = map[string][]string{
+	jsFetchCache:     {"default", "no-store", "reload", "no-cache", "force-cache", "only-if-cached"},
+	jsFetchPriority:  {"high", "low", "auto"},
+	jsFetchKeepalive: {"0", "1"},
+	jsFetchReferrerPolicy: {"", "no-referrer", "no-referrer-when-downgrade", "same-origin", "origin",
+		"strict-origin", "origin-when-cross-origin", "strict-origin-when-cross-origin", "unsafe-url"},
+}
+
+// validateFetchEnum reports an error if value is not one of the values that
+// the Fetch spec permits for the option named by key.
+func validateFetchEnum(key, value string) error {
+	for _, v := range validFetchEnumValues[key] {
+		if v == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("net/http: invalid %s header value %q", key, value)
+}
+
 // RoundTrip implements the RoundTripper interface using the WHATWG Fetch API.
 func (t *Transport) RoundTrip(req *Request) (*Response, error) {
 	// The Transport has a documented contract that states that if the DialContext or
@@ -107,6 +178,8 @@ func (t *Transport) RoundTrip(req *Request) (*Response, error) {
 		ac = ac.New()
 	}
 
+	reporter := newProgressReporter(progressOptionsFromContext(req.Context()), req.outgoingLength(), -1)
+
 	opt := js.Global().Get("Object").New()
 	// See https://developer.mozilla.org/en-US/docs/Web/API/WindowOrWorkerGlobalScope/fetch
 	// for options available.
@@ -124,6 +197,43 @@ func (t *Transport) RoundTrip(req *Request) (*Response, error) {
 		opt.Set("redirect", h)
 		req.Header.Del(jsFetchRedirect)
 	}
+	if h := req.Header.Get(jsFetchCache); h != "" {
+		if err := validateFetchEnum(jsFetchCache, h); err != nil {
+			return nil, err
+		}
+		opt.Set("cache", h)
+		req.Header.Del(jsFetchCache)
+	}
+	if h := req.Header.Get(jsFetchIntegrity); h != "" {
+		opt.Set("integrity", h)
+		req.Header.Del(jsFetchIntegrity)
+	}
+	if _, ok := req.Header[jsFetchReferrer]; ok {
+		opt.Set("referrer", req.Header.Get(jsFetchReferrer))
+		req.Header.Del(jsFetchReferrer)
+	}
+	if _, ok := req.Header[jsFetchReferrerPolicy]; ok {
+		h := req.Header.Get(jsFetchReferrerPolicy)
+		if err := validateFetchEnum(jsFetchReferrerPolicy, h); err != nil {
+			return nil, err
+		}
+		opt.Set("referrerPolicy", h)
+		req.Header.Del(jsFetchReferrerPolicy)
+	}
+	if h := req.Header.Get(jsFetchKeepalive); h != "" {
+		if err := validateFetchEnum(jsFetchKeepalive, h); err != nil {
+			return nil, err
+		}
+		opt.Set("keepalive", h == "1")
+		req.Header.Del(jsFetchKeepalive)
+	}
+	if h := req.Header.Get(jsFetchPriority); h != "" {
+		if err := validateFetchEnum(jsFetchPriority, h); err != nil {
+			return nil, err
+		}
+		opt.Set("priority", h)
+		req.Header.Del(jsFetchPriority)
+	}
 	if !ac.IsUndefined() {
 		opt.Set("signal", ac.Get("signal"))
 	}
@@ -149,9 +259,13 @@ func (t *Transport) RoundTrip(req *Request) (*Response, error) {
 				opt.Set("body", buf)
 			}
 		} else {
+			chunkSize := t.writeBufferSize()
+			if opts := progressOptionsFromContext(req.Context()); opts != nil && opts.ChunkSize > 0 {
+				chunkSize = opts.ChunkSize
+			}
 			readableStreamCtorArg := js.Global().Get("Object").New()
 			readableStreamCtorArg.Set("type", "bytes")
-			readableStreamCtorArg.Set("autoAllocateChunkSize", t.writeBufferSize())
+			readableStreamCtorArg.Set("autoAllocateChunkSize", chunkSize)
 
 			readableStreamPull = js.FuncOf(func(this js.Value, args []js.Value) any {
 				controller := args[0]
@@ -168,6 +282,7 @@ func (t *Transport) RoundTrip(req *Request) (*Response, error) {
 					buf := uint8Array.New(byobRequestView.Get("buffer"))
 					js.CopyBytesToJS(buf, bodyBuf)
 					byobRequest.Call("respond", readBytes)
+					reporter.sent(readBytes)
 				}
 
 				if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
@@ -192,6 +307,7 @@ func (t *Transport) RoundTrip(req *Request) (*Response, error) {
 		}
 	}
 
+	reporter.lifecycle(ProgressStarted)
 	fetchPromise := js.Global().Call("fetch", req.URL.String(), opt)
 	var (
 		respCh           = make(chan *Response, 1)
@@ -243,16 +359,19 @@ func (t *Transport) RoundTrip(req *Request) (*Response, error) {
 			contentLength = -1
 		}
 
+		reporter.setTotalReceived(contentLength)
+		reporter.lifecycle(ProgressHeaders)
+
 		b := result.Get("body")
 		var body io.ReadCloser
 		// The body is undefined when the browser does not support streaming response bodies (Firefox),
 		// and null in certain error cases, i.e. when the request is blocked because of CORS settings.
 		if !b.IsUndefined() && !b.IsNull() {
-			body = &streamReader{stream: b.Call("getReader")}
+			body = &streamReader{stream: b.Call("getReader"), reporter: reporter}
 		} else {
 			// Fall back to using ArrayBuffer
 			// https://developer.mozilla.org/en-US/docs/Web/API/Body/arrayBuffer
-			body = &arrayReader{arrayPromise: result.Call("arrayBuffer")}
+			body = &arrayReader{arrayPromise: result.Call("arrayBuffer"), reporter: reporter}
 		}
 
 		code := result.Get("status").Int()
@@ -302,6 +421,7 @@ func (t *Transport) RoundTrip(req *Request) (*Response, error) {
 			// Abort the Fetch request.
 			ac.Call("abort")
 		}
+		reporter.lifecycle(ProgressAborted)
 		return nil, req.Context().Err()
 	case resp := <-respCh:
 		return resp, nil
@@ -315,9 +435,11 @@ var errClosed = errors.New("net/http: reader is closed")
 // streamReader implements an io.ReadCloser wrapper for ReadableStream.
 // See https://fetch.spec.whatwg.org/#readablestream for more information.
 type streamReader struct {
-	pending []byte
-	stream  js.Value
-	err     error // sticky read error
+	pending       []byte
+	stream        js.Value
+	err           error // sticky read error
+	reporter      *progressReporter
+	firstByteSeen bool
 }
 
 func (r *streamReader) Read(p []byte) (n int, err error) {
@@ -355,13 +477,21 @@ func (r *streamReader) Read(p []byte) (n int, err error) {
 		select {
 		case b := <-bCh:
 			r.pending = b
+			if !r.firstByteSeen && len(b) > 0 {
+				r.firstByteSeen = true
+				r.reporter.lifecycle(ProgressFirstByte)
+			}
 		case err := <-errCh:
 			r.err = err
+			if err == io.EOF {
+				r.reporter.lifecycle(ProgressDone)
+			}
 			return 0, err
 		}
 	}
 	n = copy(p, r.pending)
 	r.pending = r.pending[n:]
+	r.reporter.received(n)
 	return n, nil
 }
 
@@ -383,6 +513,8 @@ type arrayReader struct {
 	pending      []byte
 	read         bool
 	err          error // sticky read error
+	reporter     *progressReporter
+	doneReported bool
 }
 
 func (r *arrayReader) Read(p []byte) (n int, err error) {
@@ -417,11 +549,19 @@ func (r *arrayReader) Read(p []byte) (n int, err error) {
 		select {
 		case b := <-bCh:
 			r.pending = b
+			if len(b) > 0 {
+				r.reporter.lifecycle(ProgressFirstByte)
+				r.reporter.received(len(b))
+			}
 		case err := <-errCh:
 			return 0, err
 		}
 	}
 	if len(r.pending) == 0 {
+		if !r.doneReported {
+			r.doneReported = true
+			r.reporter.lifecycle(ProgressDone)
+		}
 		return 0, io.EOF
 	}
 	n = copy(p, r.pending)